@@ -0,0 +1,77 @@
+/**
+ * Copyright 2019 Comcast Cable Communications Management, LLC
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+// Package eventrecord builds db records out of WRP event messages.
+package eventrecord
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	db "github.com/xmidt-org/codex-db"
+	"github.com/xmidt-org/voynicrypto"
+	"github.com/xmidt-org/wrp-go/wrp"
+	"github.com/yugabyte/gocql"
+)
+
+// EventDestinationPrefix is the start of every device status event destination.
+const EventDestinationPrefix = "event:device-status/"
+
+// ParseEventDestination gets the device id and event type out of a destination
+// of the form event:device-status/<deviceID>/<type>.  It is a local stand-in
+// for db.ParseEventDestination until codex-db provides it.
+func ParseEventDestination(dest string) (deviceID string, eventType string, err error) {
+	if !strings.HasPrefix(dest, EventDestinationPrefix) {
+		return "", "", fmt.Errorf("invalid event destination: %s", dest)
+	}
+	parts := strings.Split(strings.TrimPrefix(dest, EventDestinationPrefix), "/")
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", "", fmt.Errorf("invalid event destination: %s", dest)
+	}
+	return parts[0], parts[1], nil
+}
+
+// NewRecordFromWRP builds a state record from the message, with the device id
+// taken from the destination and the message, encoded in the given format and
+// then encrypted, as the record data.  The record dies ttl after it is born.
+func NewRecordFromWRP(msg wrp.Message, format wrp.Format, enc voynicrypto.Encrypt, ttl time.Duration) (db.Record, error) {
+	deviceID, _, err := ParseEventDestination(msg.Destination)
+	if err != nil {
+		return db.Record{}, err
+	}
+	var data []byte
+	if err = wrp.NewEncoderBytes(&data, format).Encode(&msg); err != nil {
+		return db.Record{}, err
+	}
+	encryptedData, nonce, err := enc.EncryptMessage(data)
+	if err != nil {
+		return db.Record{}, err
+	}
+	now := time.Now()
+	return db.Record{
+		Type:      db.State,
+		DeviceID:  deviceID,
+		BirthDate: now.UnixNano(),
+		DeathDate: now.Add(ttl).UnixNano(),
+		Data:      encryptedData,
+		Nonce:     nonce,
+		Alg:       string(enc.GetAlgorithm()),
+		KID:       enc.GetKID(),
+		RowID:     gocql.TimeUUID().String(),
+	}, nil
+}
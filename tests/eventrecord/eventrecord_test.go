@@ -0,0 +1,165 @@
+/**
+ * Copyright 2019 Comcast Cable Communications Management, LLC
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ *
+ */
+
+package eventrecord
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+	"time"
+
+	db "github.com/xmidt-org/codex-db"
+	"github.com/xmidt-org/voynicrypto"
+	"github.com/xmidt-org/wrp-go/wrp"
+)
+
+func TestParseEventDestination(t *testing.T) {
+	tests := []struct {
+		description       string
+		dest              string
+		expectedDeviceID  string
+		expectedEventType string
+		expectedErr       bool
+	}{
+		{
+			description:       "Success",
+			dest:              "event:device-status/mac:112233445566/online",
+			expectedDeviceID:  "mac:112233445566",
+			expectedEventType: "online",
+		},
+		{
+			description: "Wrong Prefix Error",
+			dest:        "event:something-else/mac:112233445566/online",
+			expectedErr: true,
+		},
+		{
+			description: "Missing Type Error",
+			dest:        "event:device-status/mac:1/",
+			expectedErr: true,
+		},
+		{
+			description: "Empty ID Error",
+			dest:        "event:device-status//online",
+			expectedErr: true,
+		},
+		{
+			description: "No Slash Error",
+			dest:        "event:device-status/mac:112233445566",
+			expectedErr: true,
+		},
+		{
+			description: "Extra Segment Error",
+			dest:        "event:device-status/mac:1/online/extra",
+			expectedErr: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.description, func(t *testing.T) {
+			deviceID, eventType, err := ParseEventDestination(tc.dest)
+			if tc.expectedErr != (err != nil) {
+				t.Fatalf("expected error: %v, got: %v", tc.expectedErr, err)
+			}
+			if deviceID != tc.expectedDeviceID {
+				t.Errorf("expected device id %q, got %q", tc.expectedDeviceID, deviceID)
+			}
+			if eventType != tc.expectedEventType {
+				t.Errorf("expected event type %q, got %q", tc.expectedEventType, eventType)
+			}
+		})
+	}
+}
+
+func TestNewRecordFromWRP(t *testing.T) {
+	tests := []struct {
+		description string
+		format      wrp.Format
+	}{
+		{
+			description: "Msgpack",
+			format:      wrp.Msgpack,
+		},
+		{
+			description: "JSON",
+			format:      wrp.JSON,
+		},
+	}
+
+	ttl := 5 * time.Minute
+	encrypter := &voynicrypto.NOOP{}
+	msg := wrp.Message{
+		Type:            wrp.SimpleEventMessageType,
+		Source:          "dns:talaria",
+		Destination:     "event:device-status/mac:112233445566/online",
+		ContentType:     "json",
+		TransactionUUID: "a51fecfd-2dc8-421d-ab52-1eaa7d301513",
+		Metadata:        map[string]string{"/trust": "0"},
+		Payload:         []byte(`{"id": "mac:112233445566"}`),
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.description, func(t *testing.T) {
+			record, err := NewRecordFromWRP(msg, tc.format, encrypter, ttl)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if record.Type != db.State {
+				t.Errorf("expected type %v, got %v", db.State, record.Type)
+			}
+			if record.DeviceID != "mac:112233445566" {
+				t.Errorf("expected device id %q, got %q", "mac:112233445566", record.DeviceID)
+			}
+			if record.DeathDate-record.BirthDate != ttl.Nanoseconds() {
+				t.Errorf("expected death date %v after birth date, got %v", ttl, time.Duration(record.DeathDate-record.BirthDate))
+			}
+			if record.Alg != string(encrypter.GetAlgorithm()) {
+				t.Errorf("expected alg %q, got %q", encrypter.GetAlgorithm(), record.Alg)
+			}
+			if record.KID != encrypter.GetKID() {
+				t.Errorf("expected kid %q, got %q", encrypter.GetKID(), record.KID)
+			}
+
+			var decoded wrp.Message
+			if err := wrp.NewDecoderBytes(record.Data, tc.format).Decode(&decoded); err != nil {
+				t.Fatalf("failed to decode record data: %v", err)
+			}
+			if decoded.Destination != msg.Destination ||
+				decoded.Source != msg.Source ||
+				decoded.TransactionUUID != msg.TransactionUUID ||
+				!reflect.DeepEqual(decoded.Metadata, msg.Metadata) ||
+				!bytes.Equal(decoded.Payload, msg.Payload) {
+				t.Errorf("expected decoded message %+v, got %+v", msg, decoded)
+			}
+		})
+	}
+}
+
+func TestNewRecordFromWRPInvalidDestination(t *testing.T) {
+	msg := wrp.Message{
+		Type:        wrp.SimpleEventMessageType,
+		Destination: "event:device-status/mac:112233445566",
+	}
+
+	record, err := NewRecordFromWRP(msg, wrp.Msgpack, &voynicrypto.NOOP{}, time.Hour)
+	if err == nil {
+		t.Fatal("expected an error for a malformed destination")
+	}
+	if !reflect.DeepEqual(record, db.Record{}) {
+		t.Errorf("expected an empty record, got %+v", record)
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"github.com/DATA-DOG/godog"
 	"github.com/DATA-DOG/godog/gherkin"
 	db "github.com/xmidt-org/codex-db"
+	"github.com/xmidt-org/codex-deploy/tests/eventrecord"
 	"github.com/xmidt-org/voynicrypto"
 	"github.com/xmidt-org/wrp-go/wrp"
 	"github.com/yugabyte/gocql"
@@ -14,6 +15,7 @@ import (
 	"net/http"
 	"reflect"
 	"strconv"
+	"strings"
 	"time"
 )
 
@@ -61,12 +63,6 @@ func (a *gungnirFeature) theData(data *gherkin.DataTable) error {
 	head := data.Rows[0].Cells
 	rows := []db.Record{}
 	for i := 1; i < len(data.Rows); i++ {
-		record := db.Record{
-			Type:      db.State,
-			BirthDate: time.Now().UnixNano(),
-			DeathDate: time.Now().Add(time.Hour).UnixNano(),
-			RowID:     gocql.TimeUUID().String(),
-		}
 		event := wrp.Message{
 			Type:        4,
 			Source:      "dns:talaria",
@@ -77,26 +73,31 @@ func (a *gungnirFeature) theData(data *gherkin.DataTable) error {
 			Payload:     []byte{},
 			PartnerIDs:  []string{},
 		}
+		deviceID := ""
 		holdType := ""
+		var birth, death int64
+		var hasBirth, hasDeath bool
 
 		for n, cell := range data.Rows[i].Cells {
 			switch head[n].Value {
 			case "deviceID":
-				record.DeviceID = cell.Value
+				deviceID = cell.Value
 			case "type":
 				holdType = cell.Value
 			case "birthdate":
-				birth, err := strconv.ParseInt(cell.Value, 10, 64)
+				parsed, err := strconv.ParseInt(cell.Value, 10, 64)
 				if err != nil {
 					return err
 				}
-				record.BirthDate = birth
+				birth = parsed
+				hasBirth = true
 			case "deathdate":
-				death, err := strconv.ParseInt(cell.Value, 10, 64)
+				parsed, err := strconv.ParseInt(cell.Value, 10, 64)
 				if err != nil {
 					return err
 				}
-				record.DeathDate = death
+				death = parsed
+				hasDeath = true
 			case "payload":
 				payload, err := base64.StdEncoding.DecodeString(cell.Value)
 				if err != nil {
@@ -120,16 +121,23 @@ func (a *gungnirFeature) theData(data *gherkin.DataTable) error {
 			}
 
 		}
-		event.Destination = fmt.Sprintf("event:device-status/%s/%s", record.DeviceID, holdType)
-		encrypter := voynicrypto.NOOP{}
-		encyptedData, nonce, err := encrypter.EncryptMessage(wrp.MustEncode(&event, wrp.Msgpack))
+		if deviceID == "" || holdType == "" {
+			return fmt.Errorf("row %d is missing a deviceID or type column", i)
+		}
+		if strings.Contains(deviceID, "/") || strings.Contains(holdType, "/") {
+			return fmt.Errorf("row %d has a deviceID or type containing '/'", i)
+		}
+		event.Destination = fmt.Sprintf("%s%s/%s", eventrecord.EventDestinationPrefix, deviceID, holdType)
+		record, err := eventrecord.NewRecordFromWRP(event, wrp.Msgpack, &voynicrypto.NOOP{}, time.Hour)
 		if err != nil {
 			return err
 		}
-		record.Data = encyptedData
-		record.Nonce = nonce
-		record.Alg = string(encrypter.GetAlgorithm())
-		record.KID = encrypter.GetKID()
+		if hasBirth {
+			record.BirthDate = birth
+		}
+		if hasDeath {
+			record.DeathDate = death
+		}
 		rows = append(rows, record)
 	}
 	return a.db.InsertRecords(rows...)